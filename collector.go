@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// DiskHealth is the OS-agnostic shape every HealthCollector reduces a single
+// physical disk down to. checkDiskStatusAndNotify, the /metrics gauges and the
+// /disks bot command only ever see this - never a platform tool's raw output.
+type DiskHealth struct {
+	DeviceID    string
+	Model       string
+	MediaType   string // "SSD", "HDD", or whatever the source reports
+	Wear        float64
+	Reallocated float64
+	Pending     float64
+	Uncorrected float64
+}
+
+// HealthCollector is implemented once per OS - collector_windows.go (via
+// Get-PhysicalDisk / Get-StorageReliabilityCounter) and collector_unix_smartctl.go
+// (Linux and macOS, via smartctl) - and is the only place that shells
+// out to a platform-specific tool. newPlatformCollector, also implemented per
+// OS, picks the right one at startup.
+type HealthCollector interface {
+	Collect(ctx context.Context) ([]DiskHealth, error)
+}
+
+// healthCollector is built once at package init from the build's GOOS.
+var healthCollector HealthCollector = newPlatformCollector()
+
+// diskIdentifier returns the "Disk[id](model)" key used as both the
+// currentProblems map key and the human-readable line in notifications -
+// the same format the old PowerShell-only implementation printed.
+func (d DiskHealth) diskIdentifier() string {
+	return fmt.Sprintf("Disk[%s](%s)", d.DeviceID, d.Model)
+}
+
+// line renders d as a single human-readable status line.
+func (d DiskHealth) line() string {
+	return fmt.Sprintf("%s - MediaType: %s - Wear: %g - ReallocatedSectors: %g - PendingSectors: %g - UncorrectedErrors: %g",
+		d.diskIdentifier(), d.MediaType, d.Wear, d.Reallocated, d.Pending, d.Uncorrected)
+}
+
+// hasProblem reports whether any reliability counter on d indicates trouble.
+func (d DiskHealth) hasProblem() bool {
+	return d.Reallocated > 0 || d.Pending > 0 || d.Uncorrected > 0 || d.Wear > 0
+}
@@ -0,0 +1,26 @@
+//go:build linux || darwin
+
+package main
+
+import "context"
+
+// SmartctlCollector collects disk health via "smartctl --scan" + "smartctl -a -j",
+// the same tool smart.go's enrichment pass uses, reduced to the cross-platform
+// DiskHealth shape checkDiskStatusAndNotify expects.
+type SmartctlCollector struct{}
+
+func (c *SmartctlCollector) Collect(ctx context.Context) ([]DiskHealth, error) {
+	reports, err := collectSmartReports()
+	if err != nil {
+		return nil, err
+	}
+	disks := make([]DiskHealth, 0, len(reports))
+	for _, r := range reports {
+		disks = append(disks, smartReportToDiskHealth(r))
+	}
+	return disks, nil
+}
+
+func newPlatformCollector() HealthCollector {
+	return &SmartctlCollector{}
+}
@@ -0,0 +1,101 @@
+//go:build windows
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// diskCounterPSScript запрашивает у Get-PhysicalDisk / Get-StorageReliabilityCounter
+// MediaType (SSD/HDD) и счетчики надежности для каждого физического диска.
+const diskCounterPSScript = `
+	$disks = Get-PhysicalDisk;
+	if ($null -eq $disks) { exit 0; }
+	foreach ($disk in $disks) {
+		try {
+			$counters = $disk | Get-StorageReliabilityCounter;
+			$deviceId = $disk.DeviceId;
+			$model = $disk.Model.Trim();
+			$mediaType = $disk.MediaType;
+			$wear = $counters.Wear;
+			$reallocated = $counters.ReallocatedSectors;
+			$pending = $counters.CurrentPendingSectors;
+			$uncorrected = $counters.ReadErrorsUncorrected;
+			Write-Output "Disk[$deviceId]($model) - MediaType: $mediaType - Wear: $wear - ReallocatedSectors: $reallocated - PendingSectors: $pending - UncorrectedErrors: $uncorrected";
+		} catch {
+			Write-Output "Could not get counters for a disk. Skipping.";
+		}
+	}
+`
+
+// diskHeaderRe captures the device/model/media-type prefix of one
+// diskCounterPSScript output line.
+var diskHeaderRe = regexp.MustCompile(`^Disk\[(.*?)\]\((.*?)\) - MediaType: (\S+)`)
+
+// diskCounterFieldRe finds each "Name: <digits>" counter anywhere in the line,
+// unanchored, so a counter Get-StorageReliabilityCounter left empty (Wear is
+// $null on HDDs) just doesn't match instead of failing the whole line - same
+// behavior as the original regexp.FindAllStringSubmatch-based parser.
+var diskCounterFieldRe = regexp.MustCompile(`(Wear|ReallocatedSectors|PendingSectors|UncorrectedErrors): (\d+)`)
+
+// WindowsCollector collects disk health via Get-PhysicalDisk / Get-StorageReliabilityCounter.
+type WindowsCollector struct{}
+
+func (c *WindowsCollector) Collect(ctx context.Context) ([]DiskHealth, error) {
+	cmd := exec.CommandContext(ctx, "powershell", "-NoProfile", "-ExecutionPolicy", "Bypass", "-Command", diskCounterPSScript)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run PowerShell command: %w", err)
+	}
+
+	var disks []DiskHealth
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		header := diskHeaderRe.FindStringSubmatch(line)
+		if header == nil {
+			continue
+		}
+
+		dh := DiskHealth{DeviceID: header[1], Model: header[2], MediaType: header[3]}
+		seen := make(map[string]bool, 4)
+		for _, f := range diskCounterFieldRe.FindAllStringSubmatch(line, -1) {
+			v, err := strconv.ParseFloat(f[2], 64)
+			if err != nil {
+				continue
+			}
+			seen[f[1]] = true
+			switch f[1] {
+			case "Wear":
+				dh.Wear = v
+			case "ReallocatedSectors":
+				dh.Reallocated = v
+			case "PendingSectors":
+				dh.Pending = v
+			case "UncorrectedErrors":
+				dh.Uncorrected = v
+			}
+		}
+		for _, name := range [...]string{"Wear", "ReallocatedSectors", "PendingSectors", "UncorrectedErrors"} {
+			if !seen[name] {
+				slog.Warn("disk counter missing or non-numeric, defaulting to 0", "device", dh.DeviceID, "counter", name)
+			}
+		}
+
+		disks = append(disks, dh)
+	}
+	return disks, nil
+}
+
+func newPlatformCollector() HealthCollector {
+	return &WindowsCollector{}
+}
@@ -1,28 +1,19 @@
 package main
 
 import (
-	"bufio"
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
-	"math"
-	"mime/multipart"
-	"net/http"
-	"net/url"
+	"log/slog"
 	"os"
-	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"reflect"
-	"regexp"
-	"strconv"
 	"strings"
-	"syscall"
+	"sync"
 	"time"
 
-	"golang.org/x/sys/windows/svc"
-	"golang.org/x/sys/windows/svc/mgr"
 	"gopkg.in/natefinch/lumberjack.v2"
 )
 
@@ -44,15 +35,36 @@ const (
 type Config struct {
 	TelegramToken  string `json:"telegram_token"`
 	TelegramChatID string `json:"telegram_chat_id"`
+	// Smart - настройки сбора и анализа SMART-атрибутов через smartctl (опционально)
+	Smart SmartConfig `json:"smart"`
+	// Scheduler - интервал опроса, джиттер, ретраи и тихие часы
+	Scheduler SchedulerConfig `json:"scheduler"`
+	// Notifiers - дополнительные backend'ы уведомлений сверх Telegram (smtp, webhook, matrix)
+	Notifiers []NotifierConfig `json:"notifiers"`
+	// Metrics - встроенный Prometheus /metrics эндпоинт (по умолчанию выключен)
+	Metrics MetricsConfig `json:"metrics"`
 }
 
 var (
 	AppConfig       Config // Глобальная переменная для хранения загруженных настроек
 	telegramApiBase string // URL для API теперь тоже глобальная переменная
 	// Ключ - идентификатор диска, значение - строка с ошибкой
-	lastErrorState = make(map[string]string)
+	lastErrorState   = make(map[string]string)
+	lastErrorStateMu sync.Mutex // защищает lastErrorState от гонки с ботом, читающим /status
 )
 
+// snapshotLastErrorState возвращает копию lastErrorState для безопасного чтения
+// из других горутин (например, обработчика команд бота).
+func snapshotLastErrorState() map[string]string {
+	lastErrorStateMu.Lock()
+	defer lastErrorStateMu.Unlock()
+	snapshot := make(map[string]string, len(lastErrorState))
+	for k, v := range lastErrorState {
+		snapshot[k] = v
+	}
+	return snapshot
+}
+
 // Загрузка конфигурации из файла config.json
 func loadConfig() {
 	exePath, err := os.Executable()
@@ -66,42 +78,67 @@ func loadConfig() {
 	if _, err := os.Stat(configFilePath); os.IsNotExist(err) {
 		// Файла нет, создаем шаблон
 		fmt.Printf("Config file not found. Creating a template at %s\n", configFilePath)
-		log.Printf("Config file not found. Creating a template at %s", configFilePath)
+		slog.Info("Config file not found, creating a template", "path", configFilePath)
 
 		defaultConfig := Config{
 			TelegramToken:  "YOUR_TOKEN_HERE",
 			TelegramChatID: "YOUR_CHAT_ID_HERE",
+			Smart:          defaultSmartConfig(),
+			Scheduler:      defaultSchedulerConfig(),
+			Metrics:        defaultMetricsConfig(),
 		}
 		configData, _ := json.MarshalIndent(defaultConfig, "", "  ")
 
 		if err := os.WriteFile(configFilePath, configData, 0666); err != nil {
-			log.Fatalf("Failed to write config file template: %v", err)
+			slog.Error("Failed to write config file template", "err", err)
+			os.Exit(1)
 		}
 
-		log.Fatal("Please edit the config.json file and restart the application.")
+		slog.Error("Please edit the config.json file and restart the application.")
 		os.Exit(1)
 	}
 
 	// Файл есть, читаем его
 	file, err := os.ReadFile(configFilePath)
 	if err != nil {
-		log.Fatalf("Error reading config file: %v", err)
+		slog.Error("Error reading config file", "err", err)
+		os.Exit(1)
 	}
 
 	if err := json.Unmarshal(file, &AppConfig); err != nil {
-		log.Fatalf("Error parsing config file (invalid JSON?): %v", err)
+		slog.Error("Error parsing config file (invalid JSON?)", "err", err)
+		os.Exit(1)
 	}
 
 	if AppConfig.TelegramToken == "YOUR_TOKEN_HERE" || AppConfig.TelegramChatID == "YOUR_CHAT_ID_HERE" || AppConfig.TelegramToken == "" {
-		log.Fatal("Please fill in your actual token and chat_id in config.json")
+		slog.Error("Please fill in your actual token and chat_id in config.json")
 		os.Exit(1)
 	}
 
+	if AppConfig.Smart.Thresholds == nil {
+		AppConfig.Smart = defaultSmartConfig()
+	}
+
+	if AppConfig.Scheduler.PollIntervalSeconds <= 0 {
+		AppConfig.Scheduler.PollIntervalSeconds = defaultSchedulerConfig().PollIntervalSeconds
+	}
+	if AppConfig.Scheduler.RetryMaxAttempts <= 0 {
+		AppConfig.Scheduler.RetryMaxAttempts = defaultSchedulerConfig().RetryMaxAttempts
+	}
+	if AppConfig.Scheduler.RetryInitialDelaySeconds <= 0 {
+		AppConfig.Scheduler.RetryInitialDelaySeconds = defaultSchedulerConfig().RetryInitialDelaySeconds
+	}
+	if AppConfig.Metrics.ListenAddr == "" {
+		AppConfig.Metrics.ListenAddr = defaultMetricsConfig().ListenAddr
+	}
+
 	telegramApiBase = "https://api.telegram.org/bot" + AppConfig.TelegramToken
-	log.Println("Configuration loaded successfully.")
+	buildNotifiers()
+	slog.Info("Configuration loaded successfully.")
 }
 
-// setupLogging настраивает логирование с ротацией файлов
+// setupLogging настраивает структурированное (JSON) логирование через slog,
+// пишущее в тот же lumberjack.Logger, что и раньше, так что ротация не меняется.
 func setupLogging() {
 	exePath, err := os.Executable()
 	if err != nil {
@@ -110,200 +147,179 @@ func setupLogging() {
 	exeDir := filepath.Dir(exePath)
 	logFilePath := filepath.Join(exeDir, logFileName)
 
-	// Настраиваем lumberjack для ротации логов
-	log.SetOutput(&lumberjack.Logger{
+	logWriter := &lumberjack.Logger{
 		Filename:   logFilePath, // Путь к лог-файлу
 		MaxSize:    10,          // Максимальный размер файла в мегабайтах (MB)
 		MaxBackups: 5,           // Максимальное количество старых файлов для хранения
 		MaxAge:     30,          // Максимальное количество дней для хранения старых файлов
 		Compress:   true,        // Сжимать старые файлы в .gz
-	})
+	}
 
-	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
-	log.Println("--- Application starting (with log rotation) ---")
+	slog.SetDefault(slog.New(slog.NewJSONHandler(logWriter, nil)))
+	slog.Info("--- Application starting (with log rotation) ---")
 }
 
 func main() {
 	setupLogging()
 	loadConfig()
+	startMetricsServer()
 
-	isService, err := svc.IsWindowsService()
-	if err != nil {
-		log.Fatalf("failed to determine if we are running as a service: %v", err)
-	}
-
-	if isService {
-		runService(serviceName)
+	if handleServiceEntry() {
 		return
 	}
 
 	if len(os.Args) > 1 {
 		cmd := os.Args[1]
 		switch cmd {
-		case "install":
-			err = installService(serviceName, serviceName+" Description")
-			if err != nil {
-				log.Fatalf("failed to install service: %v", err)
-			}
-			fmt.Printf("Service %s installed successfully.\n", serviceName)
-			log.Printf("Service %s installed successfully.", serviceName)
-			return
-		case "remove":
-			err = removeService(serviceName)
-			if err != nil {
-				log.Fatalf("failed to remove service: %v", err)
-			}
-			fmt.Printf("Service %s removed successfully.\n", serviceName)
-			log.Printf("Service %s removed successfully.", serviceName)
+		case "run":
+			fmt.Println("Running in foreground. Press Ctrl+C to stop.")
+			runForeground()
 			return
 		case "test":
 			fmt.Println("Running a one-time stateful check...")
-			log.Println("Manual test run triggered.")
+			slog.Info("Manual test run triggered.")
 
 			checkDiskStatusAndNotify()
 
+			snapshot := snapshotLastErrorState()
 			var summaryMessage string
-			if len(lastErrorState) == 0 {
+			if len(snapshot) == 0 {
 				summaryMessage = "✅ Test complete. No active problems found."
 			} else {
 				var problems []string
-				for _, problemLine := range lastErrorState {
+				for _, problemLine := range snapshot {
 					problems = append(problems, problemLine)
 				}
 				summaryMessage = fmt.Sprintf("ℹ️ Test complete. Current active problems:\n\n`%s`", strings.Join(problems, "`\n`"))
 			}
-			log.Println("Sending test summary notification.")
-			sendTelegramNotification(summaryMessage)
+			slog.Info("Sending test summary notification.")
+			sendTelegramNotification(summaryMessage, false)
 
 			fmt.Println("Test complete. See log for details.")
 			return
 		default:
-			log.Fatalf("unknown command: %s", cmd)
+			if handlePlatformCommand(cmd) {
+				return
+			}
+			slog.Error("unknown command", "command", cmd)
+			os.Exit(1)
 		}
 	} else {
-		fmt.Printf("Usage:\n")
-		fmt.Printf("  %s install   - Installs the service.\n", os.Args[0])
-		fmt.Printf("  %s remove    - Removes the service.\n", os.Args[0])
-		fmt.Printf("  %s test      - Runs a one-time check and sends a summary notification.\n", os.Args[0])
+		printUsage()
 	}
 }
 
-// Service is the main service handler.
-type Service struct{}
-
-// Execute is the entry point for the service.
-func (s *Service) Execute(args []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
-	changes <- svc.Status{State: svc.StartPending, Accepts: svc.AcceptStop | svc.AcceptShutdown}
-	log.Printf("%s starting", serviceName)
+// runMainLoop запускает тикер опроса дисков, джиттер, учет тихих часов и
+// telegram-бота; используется и "run" (foreground), и сервисом Windows (Service.Execute).
+// Возвращается, когда ctx отменяется.
+func runMainLoop(ctx context.Context) {
+	baseInterval := time.Duration(AppConfig.Scheduler.PollIntervalSeconds) * time.Second
+	ticker := time.NewTicker(jitteredInterval(baseInterval, AppConfig.Scheduler.JitterPercent))
+	defer ticker.Stop()
 
-	const cmdsAccepted = svc.AcceptStop | svc.AcceptShutdown
-	changes <- svc.Status{State: svc.Running, Accepts: cmdsAccepted}
-	log.Printf("%s started", serviceName)
+	wasQuiet := activeQuietRange(time.Now(), AppConfig.Scheduler.QuietHours) != nil
 
-	ticker := time.NewTicker(1 * time.Hour)
-	defer ticker.Stop()
+	botCtx, cancelBot := context.WithCancel(ctx)
+	defer cancelBot()
+	go runTelegramBot(botCtx)
 
-	done := make(chan struct{})
+	slog.Info("Main loop running.")
 
-	go func() {
-		for {
-			select {
-			case <-ticker.C:
-				checkDiskStatusAndNotify()
-			case <-done:
-				return
+	for {
+		select {
+		case <-ticker.C:
+			nowQuiet := activeQuietRange(time.Now(), AppConfig.Scheduler.QuietHours) != nil
+			if wasQuiet && !nowQuiet {
+				slog.Info("Quiet hours window ended, flushing digest.")
+				flushQuietDigest()
 			}
-		}
-	}()
+			wasQuiet = nowQuiet
 
-	log.Println("Service main loop running.")
-
-	for c := range r {
-		switch c.Cmd {
-		case svc.Interrogate:
-			changes <- c.CurrentStatus
-		case svc.Stop, svc.Shutdown:
-			log.Printf("%s stopping due to external command", serviceName)
-			close(done)
-			changes <- svc.Status{State: svc.StopPending}
-			return false, 0
-		default:
-			log.Printf("unexpected control request #%d", c.Cmd)
+			checkDiskStatusAndNotify()
+			ticker.Reset(jitteredInterval(baseInterval, AppConfig.Scheduler.JitterPercent))
+		case <-ctx.Done():
+			return
 		}
 	}
-	return true, 0
+}
+
+// runForeground runs the service's main loop in the foreground, under the
+// process's own supervisor (systemd, launchd, ...) rather than as a platform
+// service/daemon. Logs still go to DiskMonitorService.log (see setupLogging).
+// Stops on SIGINT/SIGTERM.
+func runForeground() {
+	ctx, stop := signal.NotifyContext(context.Background(), terminationSignals()...)
+	defer stop()
+
+	slog.Info("Running in foreground mode.")
+	runMainLoop(ctx)
+	slog.Info("Foreground run stopped.")
+}
+
+func printUsage() {
+	fmt.Printf("Usage:\n")
+	fmt.Printf("  %s run      - Runs in the foreground (systemd-friendly); stops on SIGINT/SIGTERM.\n", os.Args[0])
+	fmt.Printf("  %s test     - Runs a one-time check and sends a summary notification.\n", os.Args[0])
+	printPlatformUsage()
 }
 
 // checkDiskStatusAndNotify compares current disk state with the last known state.
 func checkDiskStatusAndNotify() {
-	// --- НАЧАЛО ИЗМЕНЕНИЙ ---
-	// PowerShell скрипт теперь запрашивает MediaType (SSD/HDD) и Wear (износ)
-	psCommand := `
-		$disks = Get-PhysicalDisk;
-		if ($null -eq $disks) { exit 0; }
-		foreach ($disk in $disks) {
-			try {
-				$counters = $disk | Get-StorageReliabilityCounter;
-				$deviceId = $disk.DeviceId;
-				$model = $disk.Model.Trim();
-				$mediaType = $disk.MediaType;
-				$wear = $counters.Wear;
-				$reallocated = $counters.ReallocatedSectors;
-				$pending = $counters.CurrentPendingSectors;
-				$uncorrected = $counters.ReadErrorsUncorrected;
-				Write-Output "Disk[$deviceId]($model) - MediaType: $mediaType - Wear: $wear - ReallocatedSectors: $reallocated - PendingSectors: $pending - UncorrectedErrors: $uncorrected";
-			} catch {
-				Write-Output "Could not get counters for a disk. Skipping.";
-			}
-		}
-	`
-	// --- КОНЕЦ ИЗМЕНЕНИЙ ---
-
-	cmd := exec.Command("powershell", "-NoProfile", "-ExecutionPolicy", "Bypass", "-Command", psCommand)
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	start := time.Now()
+	result := "ok"
+	defer func() {
+		metrics.recordCheck(result, time.Since(start))
+	}()
 
-	output, err := cmd.Output()
+	disks, err := healthCollector.Collect(context.Background())
 	if err != nil {
-		currentErrorMsg := fmt.Sprintf("Failed to run PowerShell command: %v", err)
-		if lastErrorState["powershell_error"] != currentErrorMsg {
-			log.Println(currentErrorMsg)
-			sendTelegramNotification("⚠️ " + currentErrorMsg)
-			lastErrorState = map[string]string{"powershell_error": currentErrorMsg}
+		result = "collector_error"
+		currentErrorMsg := fmt.Sprintf("Failed to collect disk health: %v", err)
+		lastErrorStateMu.Lock()
+		if lastErrorState["collector_error"] != currentErrorMsg {
+			slog.Warn(currentErrorMsg)
+			notifyWithQuietHours("⚠️ " + currentErrorMsg)
+			lastErrorState = map[string]string{"collector_error": currentErrorMsg}
 		}
+		lastErrorStateMu.Unlock()
 		return
 	}
 
-	outputStr := string(output)
-	log.Printf("PowerShell check result:\n%s", outputStr)
+	slog.Info("Disk health collected", "disk_count", len(disks))
 
 	currentProblems := make(map[string]string)
-	// --- НАЧАЛО ИЗМЕНЕНИЙ ---
-	// Регулярное выражение теперь ищет и параметр Wear
-	re := regexp.MustCompile(`(ReallocatedSectors|PendingSectors|UncorrectedErrors|Wear):\s*(\d+)`)
-	// --- КОНЕЦ ИЗМЕНЕНИЙ ---
-
-	scanner := bufio.NewScanner(strings.NewReader(outputStr))
-	for scanner.Scan() {
-		line := scanner.Text()
-		matches := re.FindAllStringSubmatch(line, -1)
-		hasProblem := false
-		for _, match := range matches {
-			value, _ := strconv.Atoi(match[2])
-			if value > 0 {
-				hasProblem = true
-				break
-			}
+	for _, d := range disks {
+		metrics.recordDiskGauges(d.DeviceID, d.Model, d.MediaType, d.Wear, d.Reallocated, d.Pending, d.Uncorrected)
+		if d.hasProblem() {
+			currentProblems[d.diskIdentifier()] = d.line()
+			slog.Warn("Disk reported a problem", "disk_id", d.DeviceID, "model", d.Model, "line", d.line())
 		}
-		if hasProblem {
-			diskIdentifier := strings.Split(line, " - ")[0]
-			currentProblems[diskIdentifier] = line
+	}
+
+	// Если в PATH есть smartctl, дополняем данные collector'а разбором SMART-атрибутов
+	if AppConfig.Smart.Enabled && smartctlAvailable() {
+		reports, err := collectSmartReports()
+		if err != nil {
+			slog.Warn("SMART collection failed", "err", err)
+		} else {
+			state := loadSmartState()
+			for device, problem := range evaluateSmartReports(reports, AppConfig.Smart, &state) {
+				currentProblems[device] = problem
+			}
+			saveSmartState(state)
 		}
 	}
 
+	if len(currentProblems) > 0 {
+		result = "failure"
+	}
+
+	lastErrorStateMu.Lock()
+	defer lastErrorStateMu.Unlock()
+
 	if !reflect.DeepEqual(currentProblems, lastErrorState) {
-		log.Println("Disk status has changed. Sending notification.")
+		slog.Info("Disk status has changed. Sending notification.")
 
-		// --- НАЧАЛО ИЗМЕНЕНИЙ ---
 		// Формирование красивого сообщения с использованием Markdown
 		var messageBuilder strings.Builder
 		messageBuilder.WriteString("Disk health status has changed!\n\n")
@@ -322,174 +338,22 @@ func checkDiskStatusAndNotify() {
 				messageBuilder.WriteString(fmt.Sprintf("🟢 **Problem Resolved:**\n`%s` is now OK.\n\n", disk))
 			}
 		}
-		// --- КОНЕЦ ИЗМЕНЕНИЙ ---
 
-		sendTelegramNotification(messageBuilder.String())
+		notifyWithQuietHours(messageBuilder.String())
 		lastErrorState = currentProblems
 	} else {
-		log.Println("Disk status unchanged. No notification needed.")
+		slog.Info("Disk status unchanged. No notification needed.")
 	}
 }
 
-// sendTelegramNotification formats and sends a message to Telegram with a retry mechanism.
-func sendTelegramNotification(message string) {
-	hostname, _ := os.Hostname()
-	fullMessage := fmt.Sprintf("🖥️ **Host:** `%s`\n\n%s", hostname, message)
-
-	var err error
-
-	for i := 0; i <= maxRetries; i++ {
-		if len(fullMessage) > telegramMsgLimit {
-			err = sendTelegramDocument(fullMessage)
-		} else {
-			err = sendTelegramText(fullMessage, false)
-		}
-
-		if err == nil {
-			log.Println("Telegram notification sent successfully.")
-			return
-		}
-
-		log.Printf("Failed to send notification (attempt %d/%d): %v", i+1, maxRetries+1, err)
-
-		if i == maxRetries {
-			break
-		}
-
-		delay := initialRetryDelay * time.Duration(math.Pow(3, float64(i)))
-		log.Printf("Waiting for %v before retrying...", delay)
-		time.Sleep(delay)
-	}
-
-	log.Printf("Gave up sending notification after %d attempts.", maxRetries+1)
-}
-
-// sendTelegramText sends a short message.
-func sendTelegramText(message string, silent bool) error {
-	apiURL := fmt.Sprintf("%s/sendMessage", telegramApiBase)
-	params := url.Values{}
-	params.Add("chat_id", AppConfig.TelegramChatID)
-	params.Add("text", message)
-	params.Add("parse_mode", "Markdown")
-	if silent {
-		params.Add("disable_notification", "true")
-	}
-
-	resp, err := http.PostForm(apiURL, params)
-	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("request failed with status %s: %s", resp.Status, string(body))
-	}
-	return nil
-}
-
-// sendTelegramDocument sends a long message as a text file.
-func sendTelegramDocument(content string) error {
-	var b bytes.Buffer
-	w := multipart.NewWriter(&b)
-
-	w.WriteField("chat_id", AppConfig.TelegramChatID)
-
-	now := time.Now().Format("2006-01-02_15-04-05")
-	hostname, _ := os.Hostname()
-	fileName := fmt.Sprintf("log_%s_%s.txt", hostname, now)
-
-	fw, err := w.CreateFormFile("document", fileName)
-	if err != nil {
-		return fmt.Errorf("failed to create form file: %w", err)
-	}
-	if _, err := fw.Write([]byte(content)); err != nil {
-		return fmt.Errorf("failed to write content to form file: %w", err)
-	}
-	w.Close()
-
-	apiURL := fmt.Sprintf("%s/sendDocument", telegramApiBase)
-	req, err := http.NewRequest("POST", apiURL, &b)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", w.FormDataContentType())
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("request failed with status %s: %s", resp.Status, string(body))
-	}
-	return nil
-}
-
-// installService installs the service.
-func installService(name, desc string) error {
-	m, err := mgr.Connect()
-	if err != nil {
-		return err
-	}
-	defer m.Disconnect()
-
-	s, err := m.OpenService(name)
-	if err == nil {
-		s.Close()
-		return fmt.Errorf("service %s already exists", name)
-	}
-
-	exepath, err := os.Executable()
-	if err != nil {
-		return err
-	}
-
-	s, err = m.CreateService(name, exepath, mgr.Config{
-		DisplayName: name,
-		Description: desc,
-		StartType:   mgr.StartAutomatic,
-	})
-	if err != nil {
-		return err
-	}
-	defer s.Close()
-
-	return nil
-}
-
-// removeService removes the service.
-func removeService(name string) error {
-	m, err := mgr.Connect()
-	if err != nil {
-		return err
-	}
-	defer m.Disconnect()
-
-	s, err := m.OpenService(name)
-	if err != nil {
-		return fmt.Errorf("service %s is not installed", name)
-	}
-	defer s.Close()
-
-	err = s.Delete()
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
-// runService executes the service handler.
-func runService(name string) {
-	log.Printf("Service %s starting to run...", name)
-	err := svc.Run(name, &Service{})
-	if err != nil {
-		log.Printf("Service %s failed: %v", name, err)
-		return
+// sendTelegramNotification is the funnel every alert in the service goes through.
+// It fans out via activeNotifier (Telegram plus any configured extra backends) and
+// each backend is retried independently with backoff, see MultiNotifier.
+// When silent is true the message is delivered without a notification sound (used for
+// quiet-hours digests and silent_hours mode).
+func sendTelegramNotification(message string, silent bool) {
+	ctx := withSilent(context.Background(), silent)
+	if err := activeNotifier.Send(ctx, "Disk Monitor Alert", message); err != nil {
+		slog.Error("Gave up sending notification", "err", err)
 	}
-	log.Printf("Service %s stopped.", name)
 }
@@ -0,0 +1,80 @@
+//go:build darwin
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// terminationSignals are the signals "run" stops on.
+func terminationSignals() []os.Signal {
+	return []os.Signal{os.Interrupt, syscall.SIGTERM}
+}
+
+// handleServiceEntry is a no-op on macOS: there is no SCM-style service entry
+// point, launchd always execs the binary as a plain foreground process
+// ("run"), so main() falls through to normal subcommand parsing.
+func handleServiceEntry() bool {
+	return false
+}
+
+// handlePlatformCommand handles the macOS-only "launchd-plist" subcommand,
+// which prints a launchd property list to stdout for the caller to redirect
+// into ~/Library/LaunchAgents or /Library/LaunchDaemons.
+func handlePlatformCommand(cmd string) bool {
+	switch cmd {
+	case "launchd-plist":
+		plist, err := generateLaunchdPlist()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to generate launchd plist: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(plist)
+		return true
+	default:
+		return false
+	}
+}
+
+func printPlatformUsage() {
+	fmt.Printf("  %s launchd-plist - Prints a launchd plist; redirect into ~/Library/LaunchAgents.\n", os.Args[0])
+}
+
+const launchdPlistTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>com.theurs.diskmonitorservice</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>%s</string>
+		<string>run</string>
+	</array>
+	<key>WorkingDirectory</key>
+	<string>%s</string>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardOutPath</key>
+	<string>%s/launchd.out.log</string>
+	<key>StandardErrorPath</key>
+	<string>%s/launchd.err.log</string>
+</dict>
+</plist>
+`
+
+// generateLaunchdPlist renders a launchd plist that runs this binary's "run"
+// (foreground) subcommand, KeepAlive'd, from its own directory.
+func generateLaunchdPlist() (string, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	exeDir := filepath.Dir(exePath)
+	return fmt.Sprintf(launchdPlistTemplate, exePath, exeDir, exeDir, exeDir), nil
+}
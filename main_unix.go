@@ -0,0 +1,28 @@
+//go:build !windows && !darwin
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// terminationSignals are the signals "run" stops on.
+func terminationSignals() []os.Signal {
+	return []os.Signal{os.Interrupt, syscall.SIGTERM}
+}
+
+// handleServiceEntry is a no-op on Linux: there is no SCM-style service entry
+// point, systemd always execs the binary as a plain foreground process
+// ("run"), so main() falls through to normal subcommand parsing.
+func handleServiceEntry() bool {
+	return false
+}
+
+// handlePlatformCommand has nothing platform-specific to offer on Linux; a
+// systemd unit just invokes "run" directly (ExecStart=/path/to/binary run).
+func handlePlatformCommand(cmd string) bool {
+	return false
+}
+
+func printPlatformUsage() {}
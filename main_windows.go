@@ -0,0 +1,160 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// terminationSignals are the signals "run" stops on; Windows only ever sends
+// os.Interrupt (Ctrl+C) to a console process, there is no SIGTERM.
+func terminationSignals() []os.Signal {
+	return []os.Signal{os.Interrupt}
+}
+
+// handleServiceEntry runs the process as a Windows service if the SCM launched
+// it that way, returning true so main() does not also try to parse os.Args.
+func handleServiceEntry() bool {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		slog.Error("failed to determine if we are running as a service", "err", err)
+		os.Exit(1)
+	}
+	if !isService {
+		return false
+	}
+	runService(serviceName)
+	return true
+}
+
+// handlePlatformCommand handles the Windows-only "install"/"remove" subcommands.
+func handlePlatformCommand(cmd string) bool {
+	switch cmd {
+	case "install":
+		if err := installService(serviceName, serviceName+" Description"); err != nil {
+			slog.Error("failed to install service", "err", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Service %s installed successfully.\n", serviceName)
+		slog.Info("Service installed successfully.", "service", serviceName)
+		return true
+	case "remove":
+		if err := removeService(serviceName); err != nil {
+			slog.Error("failed to remove service", "err", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Service %s removed successfully.\n", serviceName)
+		slog.Info("Service removed successfully.", "service", serviceName)
+		return true
+	default:
+		return false
+	}
+}
+
+func printPlatformUsage() {
+	fmt.Printf("  %s install   - Installs the Windows service.\n", os.Args[0])
+	fmt.Printf("  %s remove    - Removes the Windows service.\n", os.Args[0])
+}
+
+// Service is the Windows service handler.
+type Service struct{}
+
+// Execute is the entry point for the service.
+func (s *Service) Execute(args []string, r <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.StartPending, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	slog.Info("Service starting", "service", serviceName)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go runMainLoop(ctx)
+
+	const cmdsAccepted = svc.AcceptStop | svc.AcceptShutdown
+	changes <- svc.Status{State: svc.Running, Accepts: cmdsAccepted}
+	slog.Info("Service started", "service", serviceName)
+
+	for c := range r {
+		switch c.Cmd {
+		case svc.Interrogate:
+			changes <- c.CurrentStatus
+		case svc.Stop, svc.Shutdown:
+			slog.Info("Service stopping due to external command", "service", serviceName)
+			cancel()
+			changes <- svc.Status{State: svc.StopPending}
+			return false, 0
+		default:
+			slog.Warn("unexpected control request", "request", c.Cmd)
+		}
+	}
+	return true, 0
+}
+
+// installService installs the service.
+func installService(name, desc string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(name)
+	if err == nil {
+		s.Close()
+		return fmt.Errorf("service %s already exists", name)
+	}
+
+	exepath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	s, err = m.CreateService(name, exepath, mgr.Config{
+		DisplayName: name,
+		Description: desc,
+		StartType:   mgr.StartAutomatic,
+	})
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	return nil
+}
+
+// removeService removes the service.
+func removeService(name string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", name)
+	}
+	defer s.Close()
+
+	err = s.Delete()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// runService executes the service handler.
+func runService(name string) {
+	slog.Info("Service starting to run...", "service", name)
+	err := svc.Run(name, &Service{})
+	if err != nil {
+		slog.Error("Service failed", "service", name, "err", err)
+		return
+	}
+	slog.Info("Service stopped.", "service", name)
+}
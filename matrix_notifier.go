@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// MatrixNotifier posts alerts to a Matrix room via the Client-Server API
+// (PUT /_matrix/client/v3/rooms/{roomId}/send/m.room.message/{txnId}).
+type MatrixNotifier struct {
+	HomeserverURL string
+	RoomID        string
+	AccessToken   string
+}
+
+func newMatrixNotifier(cfg NotifierConfig) *MatrixNotifier {
+	return &MatrixNotifier{
+		HomeserverURL: cfg.MatrixHomeserverURL,
+		RoomID:        cfg.MatrixRoomID,
+		AccessToken:   cfg.MatrixAccessToken,
+	}
+}
+
+type matrixMessageEvent struct {
+	MsgType string `json:"msgtype"`
+	Body    string `json:"body"`
+}
+
+// Name identifies this backend in diskmon_notification_send_total.
+func (m *MatrixNotifier) Name() string { return "matrix" }
+
+// Send ignores attachments and the silent hint; neither maps onto a plain m.room.message event.
+func (m *MatrixNotifier) Send(ctx context.Context, subject, body string, attachments ...Attachment) error {
+	txnID := matrixTxnID()
+	apiURL := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		m.HomeserverURL, m.RoomID, txnID)
+
+	payload, err := json.Marshal(matrixMessageEvent{
+		MsgType: "m.text",
+		Body:    fmt.Sprintf("%s\n\n%s", subject, body),
+	})
+	if err != nil {
+		return fmt.Errorf("matrix: failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, apiURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("matrix: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+m.AccessToken)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("matrix: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("matrix: request failed with status %s: %s", resp.Status, string(respBody))
+	}
+	return nil
+}
+
+// matrixTxnID returns a transaction id unique enough for this process;
+// Matrix only requires uniqueness per access token.
+func matrixTxnID() string {
+	return fmt.Sprintf("diskmon-%d", time.Now().UnixNano())
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MetricsConfig controls the embedded Prometheus /metrics endpoint. Disabled by default.
+type MetricsConfig struct {
+	Enabled    bool   `json:"enabled"`
+	ListenAddr string `json:"listen_addr"`
+}
+
+func defaultMetricsConfig() MetricsConfig {
+	return MetricsConfig{Enabled: false, ListenAddr: ":9115"}
+}
+
+// checkDurationBuckets are the histogram bucket boundaries (seconds) for diskmon_check_duration_seconds.
+var checkDurationBuckets = []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60}
+
+type diskMetricKey struct {
+	device string
+	model  string
+}
+
+type diskWearKey struct {
+	device    string
+	model     string
+	mediaType string
+}
+
+type notifierMetricKey struct {
+	backend string
+	result  string
+}
+
+// histogram is a minimal cumulative histogram, sufficient for a single gauge-ish metric
+// exposed over /metrics; no need for a full Prometheus client library.
+type histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sum += v
+	h.count++
+	for i, b := range h.buckets {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+}
+
+// metricsRegistry holds every diskmon_* metric in process memory.
+type metricsRegistry struct {
+	mu sync.Mutex
+
+	checkTotal         map[string]uint64
+	checkDuration      *histogram
+	diskReallocated    map[diskMetricKey]float64
+	diskPending        map[diskMetricKey]float64
+	diskUncorrected    map[diskMetricKey]float64
+	diskWearPercent    map[diskWearKey]float64
+	notificationTotal  map[notifierMetricKey]uint64
+	lastCheckTimestamp float64
+}
+
+var metrics = &metricsRegistry{
+	checkTotal:        make(map[string]uint64),
+	checkDuration:     newHistogram(checkDurationBuckets),
+	diskReallocated:   make(map[diskMetricKey]float64),
+	diskPending:       make(map[diskMetricKey]float64),
+	diskUncorrected:   make(map[diskMetricKey]float64),
+	diskWearPercent:   make(map[diskWearKey]float64),
+	notificationTotal: make(map[notifierMetricKey]uint64),
+}
+
+// recordCheck accounts one checkDiskStatusAndNotify run: result is one of
+// "ok", "collector_error", "failure".
+func (m *metricsRegistry) recordCheck(result string, duration time.Duration) {
+	m.mu.Lock()
+	m.checkTotal[result]++
+	m.lastCheckTimestamp = float64(time.Now().Unix())
+	m.mu.Unlock()
+	m.checkDuration.observe(duration.Seconds())
+}
+
+func (m *metricsRegistry) recordDiskGauges(device, model, mediaType string, wear, reallocated, pending, uncorrected float64) {
+	key := diskMetricKey{device: device, model: model}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.diskReallocated[key] = reallocated
+	m.diskPending[key] = pending
+	m.diskUncorrected[key] = uncorrected
+	if mediaType != "" {
+		m.diskWearPercent[diskWearKey{device: device, model: model, mediaType: mediaType}] = wear
+	}
+}
+
+func (m *metricsRegistry) recordNotification(backend, result string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notificationTotal[notifierMetricKey{backend: backend, result: result}]++
+}
+
+// writeTo renders every metric in Prometheus text exposition format.
+func (m *metricsRegistry) writeTo(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP diskmon_check_total Total number of disk checks by result.")
+	fmt.Fprintln(w, "# TYPE diskmon_check_total counter")
+	for _, result := range sortedStringKeys(m.checkTotal) {
+		fmt.Fprintf(w, "diskmon_check_total{result=%q} %d\n", result, m.checkTotal[result])
+	}
+
+	fmt.Fprintln(w, "# HELP diskmon_check_duration_seconds Duration of a disk check run.")
+	fmt.Fprintln(w, "# TYPE diskmon_check_duration_seconds histogram")
+	h := m.checkDuration
+	h.mu.Lock()
+	for i, b := range h.buckets {
+		fmt.Fprintf(w, "diskmon_check_duration_seconds_bucket{le=\"%g\"} %d\n", b, h.counts[i])
+	}
+	fmt.Fprintf(w, "diskmon_check_duration_seconds_bucket{le=\"+Inf\"} %d\n", h.count)
+	fmt.Fprintf(w, "diskmon_check_duration_seconds_sum %g\n", h.sum)
+	fmt.Fprintf(w, "diskmon_check_duration_seconds_count %d\n", h.count)
+	h.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP diskmon_disk_reallocated_sectors Reallocated sector count per disk.")
+	fmt.Fprintln(w, "# TYPE diskmon_disk_reallocated_sectors gauge")
+	for _, k := range sortedDiskKeys(m.diskReallocated) {
+		fmt.Fprintf(w, "diskmon_disk_reallocated_sectors{device=%q,model=%q} %g\n", k.device, k.model, m.diskReallocated[k])
+	}
+
+	fmt.Fprintln(w, "# HELP diskmon_disk_pending_sectors Pending sector count per disk.")
+	fmt.Fprintln(w, "# TYPE diskmon_disk_pending_sectors gauge")
+	for _, k := range sortedDiskKeys(m.diskPending) {
+		fmt.Fprintf(w, "diskmon_disk_pending_sectors{device=%q,model=%q} %g\n", k.device, k.model, m.diskPending[k])
+	}
+
+	fmt.Fprintln(w, "# HELP diskmon_disk_uncorrected_errors Uncorrected read error count per disk.")
+	fmt.Fprintln(w, "# TYPE diskmon_disk_uncorrected_errors gauge")
+	for _, k := range sortedDiskKeys(m.diskUncorrected) {
+		fmt.Fprintf(w, "diskmon_disk_uncorrected_errors{device=%q,model=%q} %g\n", k.device, k.model, m.diskUncorrected[k])
+	}
+
+	fmt.Fprintln(w, "# HELP diskmon_disk_wear_percent Reported wear/life-used percentage per disk.")
+	fmt.Fprintln(w, "# TYPE diskmon_disk_wear_percent gauge")
+	for _, k := range sortedWearKeys(m.diskWearPercent) {
+		fmt.Fprintf(w, "diskmon_disk_wear_percent{device=%q,model=%q,media_type=%q} %g\n", k.device, k.model, k.mediaType, m.diskWearPercent[k])
+	}
+
+	fmt.Fprintln(w, "# HELP diskmon_notification_send_total Notification delivery attempts by backend and result.")
+	fmt.Fprintln(w, "# TYPE diskmon_notification_send_total counter")
+	for _, k := range sortedNotifierKeys(m.notificationTotal) {
+		fmt.Fprintf(w, "diskmon_notification_send_total{backend=%q,result=%q} %d\n", k.backend, k.result, m.notificationTotal[k])
+	}
+
+	fmt.Fprintln(w, "# HELP diskmon_last_check_timestamp_seconds Unix timestamp of the last completed disk check.")
+	fmt.Fprintln(w, "# TYPE diskmon_last_check_timestamp_seconds gauge")
+	fmt.Fprintf(w, "diskmon_last_check_timestamp_seconds %g\n", m.lastCheckTimestamp)
+}
+
+func sortedStringKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedDiskKeys(m map[diskMetricKey]float64) []diskMetricKey {
+	keys := make([]diskMetricKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].device != keys[j].device {
+			return keys[i].device < keys[j].device
+		}
+		return keys[i].model < keys[j].model
+	})
+	return keys
+}
+
+func sortedWearKeys(m map[diskWearKey]float64) []diskWearKey {
+	keys := make([]diskWearKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].device != keys[j].device {
+			return keys[i].device < keys[j].device
+		}
+		if keys[i].model != keys[j].model {
+			return keys[i].model < keys[j].model
+		}
+		return keys[i].mediaType < keys[j].mediaType
+	})
+	return keys
+}
+
+func sortedNotifierKeys(m map[notifierMetricKey]uint64) []notifierMetricKey {
+	keys := make([]notifierMetricKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].backend != keys[j].backend {
+			return keys[i].backend < keys[j].backend
+		}
+		return keys[i].result < keys[j].result
+	})
+	return keys
+}
+
+// startMetricsServer starts the embedded /metrics endpoint if enabled in config.
+// It is disabled by default and never blocks the caller.
+func startMetricsServer() {
+	if !AppConfig.Metrics.Enabled {
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		metrics.writeTo(w)
+	})
+
+	addr := AppConfig.Metrics.ListenAddr
+	go func() {
+		slog.Info("Starting metrics server", "addr", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			slog.Error("Metrics server stopped", "err", err)
+		}
+	}()
+}
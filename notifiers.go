@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Attachment is a named blob of bytes delivered alongside a notification
+// (a log tail, a disk snapshot, ...) for backends that support it.
+type Attachment struct {
+	Filename string
+	Content  []byte
+}
+
+// Notifier is implemented by every alert backend (Telegram, SMTP, generic
+// webhook, Matrix, ...). Backends that don't support attachments or the
+// silent delivery hint (see withSilent) are free to ignore them.
+type Notifier interface {
+	Send(ctx context.Context, subject, body string, attachments ...Attachment) error
+	// Name is the lowercase backend identifier used as the "backend" label on
+	// diskmon_notification_send_total - not a Go type name.
+	Name() string
+}
+
+type silentCtxKeyType struct{}
+
+var silentCtxKey = silentCtxKeyType{}
+
+// withSilent attaches the "silent" delivery hint to ctx; currently only
+// TelegramNotifier honors it (maps to Telegram's disable_notification).
+func withSilent(ctx context.Context, silent bool) context.Context {
+	return context.WithValue(ctx, silentCtxKey, silent)
+}
+
+func silentFromContext(ctx context.Context) bool {
+	silent, _ := ctx.Value(silentCtxKey).(bool)
+	return silent
+}
+
+// NotifierConfig describes one entry of the config.json "notifiers" array.
+// Only the fields relevant to Type need to be filled in.
+type NotifierConfig struct {
+	Type    string `json:"type"` // "telegram", "smtp", "webhook", "matrix"
+	Enabled bool   `json:"enabled"`
+
+	SMTPHost     string   `json:"smtp_host,omitempty"`
+	SMTPPort     int      `json:"smtp_port,omitempty"`
+	SMTPUsername string   `json:"smtp_username,omitempty"`
+	SMTPPassword string   `json:"smtp_password,omitempty"`
+	SMTPFrom     string   `json:"smtp_from,omitempty"`
+	SMTPTo       []string `json:"smtp_to,omitempty"`
+
+	WebhookURL      string            `json:"webhook_url,omitempty"`
+	WebhookHeaders  map[string]string `json:"webhook_headers,omitempty"`
+	WebhookTemplate string            `json:"webhook_template,omitempty"`
+
+	MatrixHomeserverURL string `json:"matrix_homeserver_url,omitempty"`
+	MatrixRoomID        string `json:"matrix_room_id,omitempty"`
+	MatrixAccessToken   string `json:"matrix_access_token,omitempty"`
+}
+
+// activeNotifier is the funnel every alert in the service goes through; it is
+// built once from AppConfig by buildNotifiers and always includes Telegram,
+// plus whichever extra backends are enabled in config.json.
+var activeNotifier Notifier
+
+// buildNotifiers assembles activeNotifier from AppConfig. Telegram is always
+// included (TelegramToken/TelegramChatID are mandatory), any entries in
+// AppConfig.Notifiers are added on top of it.
+func buildNotifiers() {
+	notifiers := []Notifier{&TelegramNotifier{}}
+
+	for _, nc := range AppConfig.Notifiers {
+		if !nc.Enabled {
+			continue
+		}
+		switch nc.Type {
+		case "smtp":
+			notifiers = append(notifiers, newSMTPNotifier(nc))
+		case "webhook":
+			notifiers = append(notifiers, newWebhookNotifier(nc))
+		case "matrix":
+			notifiers = append(notifiers, newMatrixNotifier(nc))
+		case "telegram":
+			// уже включен по умолчанию
+		default:
+			slog.Warn("Ignoring notifier config with unknown type", "type", nc.Type)
+		}
+	}
+
+	activeNotifier = &MultiNotifier{
+		Notifiers:    notifiers,
+		MaxAttempts:  AppConfig.Scheduler.RetryMaxAttempts,
+		InitialDelay: time.Duration(AppConfig.Scheduler.RetryInitialDelaySeconds) * time.Second,
+	}
+}
+
+// MultiNotifier fans a single alert out to every configured backend
+// concurrently and retries each backend independently with exponential
+// backoff, so one flaky channel cannot block or drop alerts on the others.
+type MultiNotifier struct {
+	Notifiers    []Notifier
+	MaxAttempts  int
+	InitialDelay time.Duration
+}
+
+// Name identifies this backend in diskmon_notification_send_total; MultiNotifier
+// itself is never a retry target, but it must satisfy Notifier to be activeNotifier.
+func (m *MultiNotifier) Name() string { return "multi" }
+
+func (m *MultiNotifier) Send(ctx context.Context, subject, body string, attachments ...Attachment) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(m.Notifiers))
+
+	for i, n := range m.Notifiers {
+		wg.Add(1)
+		go func(i int, n Notifier) {
+			defer wg.Done()
+			errs[i] = m.sendWithRetry(ctx, n, subject, body, attachments...)
+		}(i, n)
+	}
+	wg.Wait()
+
+	var failures []string
+	for _, err := range errs {
+		if err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%d/%d notifier(s) failed: %s", len(failures), len(m.Notifiers), strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func (m *MultiNotifier) sendWithRetry(ctx context.Context, n Notifier, subject, body string, attachments ...Attachment) error {
+	backend := n.Name()
+	maxAttempts := m.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = maxRetries
+	}
+	delay := m.InitialDelay
+	if delay <= 0 {
+		delay = initialRetryDelay
+	}
+
+	var err error
+	for i := 0; i <= maxAttempts; i++ {
+		err = n.Send(ctx, subject, body, attachments...)
+		if err == nil {
+			metrics.recordNotification(backend, "ok")
+			return nil
+		}
+		slog.Warn("notifier send attempt failed", "backend", backend, "attempt", i+1, "max_attempts", maxAttempts+1, "err", err)
+		if i == maxAttempts {
+			break
+		}
+		time.Sleep(delay * time.Duration(math.Pow(3, float64(i))))
+	}
+	metrics.recordNotification(backend, "error")
+	return err
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QuietHoursRange описывает интервал суток (локальное время), в течение которого
+// уведомления обрабатываются особым образом: "digest" - копятся и отправляются
+// одним сообщением по окончании интервала, "silent" - отправляются сразу же,
+// но без звука (sendTelegramText вызывается с silent=true).
+type QuietHoursRange struct {
+	Start string `json:"start"` // формат "HH:MM"
+	End   string `json:"end"`   // формат "HH:MM", может быть меньше Start (интервал через полночь)
+	Mode  string `json:"mode"`  // "digest" (по умолчанию) или "silent"
+}
+
+// SchedulerConfig задает периодичность опроса, джиттер и параметры ретраев,
+// ранее зашитые константами.
+type SchedulerConfig struct {
+	PollIntervalSeconds      int               `json:"poll_interval_seconds"`
+	JitterPercent            float64           `json:"jitter_percent"`
+	RetryMaxAttempts         int               `json:"retry_max_attempts"`
+	RetryInitialDelaySeconds int               `json:"retry_initial_delay_seconds"`
+	QuietHours               []QuietHoursRange `json:"quiet_hours"`
+}
+
+func defaultSchedulerConfig() SchedulerConfig {
+	return SchedulerConfig{
+		PollIntervalSeconds:      3600,
+		JitterPercent:            0.1,
+		RetryMaxAttempts:         maxRetries,
+		RetryInitialDelaySeconds: 5,
+	}
+}
+
+var (
+	quietDigestMu       sync.Mutex
+	quietDigestMessages []string
+)
+
+// jitteredInterval возвращает base, случайно сдвинутый в пределах ±percent,
+// чтобы одновременно запущенные сервисы на разных хостах не опрашивали диски синхронно.
+func jitteredInterval(base time.Duration, percent float64) time.Duration {
+	if percent <= 0 {
+		return base
+	}
+	delta := float64(base) * percent
+	offset := (rand.Float64()*2 - 1) * delta // [-delta, +delta]
+	result := time.Duration(float64(base) + offset)
+	if result <= 0 {
+		return base
+	}
+	return result
+}
+
+func parseClock(value string) (hour, minute int, err error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	hour, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid hour in %q: %w", value, err)
+	}
+	minute, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid minute in %q: %w", value, err)
+	}
+	return hour, minute, nil
+}
+
+// activeQuietRange возвращает указатель на диапазон из ranges, в который попадает
+// момент времени now (по времени часы:минуты, без учета даты), либо nil, если ни
+// один диапазон не активен. Диапазоны, пересекающие полночь (Start > End), поддерживаются.
+func activeQuietRange(now time.Time, ranges []QuietHoursRange) *QuietHoursRange {
+	nowMinutes := now.Hour()*60 + now.Minute()
+
+	for i := range ranges {
+		r := &ranges[i]
+		startH, startM, err := parseClock(r.Start)
+		if err != nil {
+			slog.Warn("Ignoring invalid quiet_hours range", "err", err)
+			continue
+		}
+		endH, endM, err := parseClock(r.End)
+		if err != nil {
+			slog.Warn("Ignoring invalid quiet_hours range", "err", err)
+			continue
+		}
+		start := startH*60 + startM
+		end := endH*60 + endM
+
+		if start == end {
+			continue
+		}
+
+		if start < end {
+			if nowMinutes >= start && nowMinutes < end {
+				return r
+			}
+		} else {
+			// интервал через полночь, например 22:00 - 07:00
+			if nowMinutes >= start || nowMinutes < end {
+				return r
+			}
+		}
+	}
+
+	return nil
+}
+
+// notifyWithQuietHours решает, отправлять ли сообщение немедленно, молча, или
+// отложить его в дайджест в зависимости от текущих quiet_hours.
+func notifyWithQuietHours(message string) {
+	if isMuted() {
+		slog.Info("Notifications are muted via /mute; dropping message.")
+		return
+	}
+
+	rng := activeQuietRange(time.Now(), AppConfig.Scheduler.QuietHours)
+	if rng == nil {
+		sendTelegramNotification(message, false)
+		return
+	}
+
+	if rng.Mode == "silent" {
+		sendTelegramNotification(message, true)
+		return
+	}
+
+	quietDigestMu.Lock()
+	quietDigestMessages = append(quietDigestMessages, message)
+	quietDigestMu.Unlock()
+	slog.Info("Quiet hours digest mode active; message queued instead of sent immediately.")
+}
+
+// flushQuietDigest отправляет накопленные за время quiet hours сообщения одним
+// сообщением (тихо) и очищает очередь. Вызывается на первом тике опроса после окончания окна.
+func flushQuietDigest() {
+	quietDigestMu.Lock()
+	messages := quietDigestMessages
+	quietDigestMessages = nil
+	quietDigestMu.Unlock()
+
+	if len(messages) == 0 {
+		return
+	}
+
+	digest := fmt.Sprintf("🌙 Quiet hours digest (%d event(s)):\n\n%s", len(messages), strings.Join(messages, "\n\n---\n\n"))
+	sendTelegramNotification(digest, true)
+}
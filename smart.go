@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const smartStateFileName = "smart_state.json"
+
+// SmartThreshold описывает границы warn/critical для одного SMART-атрибута
+// и максимально допустимый прирост raw-значения за окно наблюдения.
+type SmartThreshold struct {
+	Name              string `json:"name"`
+	Warn              int64  `json:"warn"`
+	Critical          int64  `json:"critical"`
+	MaxDeltaPerWindow int64  `json:"max_delta_per_window"`
+}
+
+// SmartConfig настраивает сбор и анализ SMART-атрибутов через smartctl.
+type SmartConfig struct {
+	Enabled bool `json:"enabled"`
+	// HistoryWindow - сколько последних опросов хранить для расчета прироста raw-значения за окно (M в формулировке задачи)
+	HistoryWindow int `json:"history_window"`
+	// Thresholds - ключ это ID атрибута в виде строки ("5", "187", ...)
+	Thresholds map[string]SmartThreshold `json:"thresholds"`
+}
+
+func defaultSmartConfig() SmartConfig {
+	return SmartConfig{
+		Enabled:       false,
+		HistoryWindow: 30,
+		Thresholds: map[string]SmartThreshold{
+			"5":   {Name: "Reallocated_Sector_Ct", Warn: 1, Critical: 10, MaxDeltaPerWindow: 5},
+			"187": {Name: "Reported_Uncorrect", Warn: 1, Critical: 5, MaxDeltaPerWindow: 3},
+			"197": {Name: "Current_Pending_Sector", Warn: 1, Critical: 5, MaxDeltaPerWindow: 3},
+			"231": {Name: "SSD_Life_Left", Warn: 10, Critical: 5, MaxDeltaPerWindow: 0},
+		},
+	}
+}
+
+// SmartAttribute - одна строка из таблицы атрибутов smartctl -a -j.
+type SmartAttribute struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	RawValue int64  `json:"raw_value"`
+}
+
+// SmartDiskReport - результат опроса smartctl для одного физического диска.
+type SmartDiskReport struct {
+	Device     string
+	Model      string
+	Attributes []SmartAttribute
+}
+
+// smartAttributeReading - одна историческая точка для rolling window.
+type smartAttributeReading struct {
+	Timestamp time.Time `json:"timestamp"`
+	RawValue  int64     `json:"raw_value"`
+}
+
+// smartState - персистентное состояние истории атрибутов, ключи: device -> attrID -> readings.
+type smartState struct {
+	Devices map[string]map[string][]smartAttributeReading `json:"devices"`
+}
+
+func smartStateFilePath() string {
+	exePath, err := os.Executable()
+	if err != nil {
+		return smartStateFileName
+	}
+	return filepath.Join(filepath.Dir(exePath), smartStateFileName)
+}
+
+func loadSmartState() smartState {
+	state := smartState{Devices: make(map[string]map[string][]smartAttributeReading)}
+
+	data, err := os.ReadFile(smartStateFilePath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("Failed to read smart state file", "err", err)
+		}
+		return state
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		slog.Warn("Failed to parse smart state file, starting fresh", "err", err)
+		return smartState{Devices: make(map[string]map[string][]smartAttributeReading)}
+	}
+	if state.Devices == nil {
+		state.Devices = make(map[string]map[string][]smartAttributeReading)
+	}
+	return state
+}
+
+func saveSmartState(state smartState) {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		slog.Warn("Failed to marshal smart state", "err", err)
+		return
+	}
+	if err := os.WriteFile(smartStateFilePath(), data, 0666); err != nil {
+		slog.Warn("Failed to write smart state file", "err", err)
+	}
+}
+
+// smartctlAvailable сообщает, найден ли smartctl в PATH.
+func smartctlAvailable() bool {
+	_, err := exec.LookPath("smartctl")
+	return err == nil
+}
+
+// smartctlScanResult - минимальный срез JSON-вывода "smartctl --scan -j".
+type smartctlScanResult struct {
+	Devices []struct {
+		Name string `json:"name"`
+	} `json:"devices"`
+}
+
+// smartctlInfoResult - минимальный срез JSON-вывода "smartctl -a -j <device>".
+type smartctlInfoResult struct {
+	ModelName          string `json:"model_name"`
+	AtaSmartAttributes struct {
+		Table []struct {
+			ID       int    `json:"id"`
+			Name     string `json:"name"`
+			RawValue int64  `json:"raw_value"`
+		} `json:"table"`
+	} `json:"ata_smart_attributes"`
+}
+
+// collectSmartReports запускает smartctl --scan, затем smartctl -a -j для каждого
+// найденного устройства и возвращает разобранные таблицы атрибутов.
+func collectSmartReports() ([]SmartDiskReport, error) {
+	scanOut, err := exec.Command("smartctl", "--scan", "-j").Output()
+	if err != nil {
+		return nil, fmt.Errorf("smartctl --scan failed: %w", err)
+	}
+
+	var scan smartctlScanResult
+	if err := json.Unmarshal(scanOut, &scan); err != nil {
+		return nil, fmt.Errorf("failed to parse smartctl --scan output: %w", err)
+	}
+
+	var reports []SmartDiskReport
+	for _, dev := range scan.Devices {
+		out, err := exec.Command("smartctl", "-a", "-j", dev.Name).Output()
+		if err != nil {
+			slog.Warn("smartctl -a -j failed", "disk_id", dev.Name, "err", err)
+			continue
+		}
+
+		var info smartctlInfoResult
+		if err := json.Unmarshal(out, &info); err != nil {
+			slog.Warn("failed to parse smartctl output", "disk_id", dev.Name, "err", err)
+			continue
+		}
+
+		report := SmartDiskReport{Device: dev.Name, Model: strings.TrimSpace(info.ModelName)}
+		for _, a := range info.AtaSmartAttributes.Table {
+			report.Attributes = append(report.Attributes, SmartAttribute{ID: a.ID, Name: a.Name, RawValue: a.RawValue})
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// isInvertedSmartAttr reports whether a SMART attribute's raw value is a
+// remaining-life percentage (lower is worse) rather than an error/wear
+// counter (higher is worse), so threshold comparisons can be flipped.
+func isInvertedSmartAttr(id int) bool {
+	switch id {
+	case 231, 202:
+		return true
+	default:
+		return false
+	}
+}
+
+// smartReportToDiskHealth reduces one SmartDiskReport down to the handful of
+// counters checkDiskStatusAndNotify and the /metrics gauges care about. Used
+// by the Linux/macOS HealthCollectors, which have nothing but smartctl to go on.
+// Attribute 231/202 (SSD_Life_Left / Percent_Lifetime_Remain) is a remaining-life
+// value, so it is inverted to match diskmon_disk_wear_percent's "percent used" semantics.
+func smartReportToDiskHealth(r SmartDiskReport) DiskHealth {
+	dh := DiskHealth{DeviceID: r.Device, Model: r.Model, MediaType: "HDD"}
+	for _, a := range r.Attributes {
+		switch a.ID {
+		case 5:
+			dh.Reallocated = float64(a.RawValue)
+		case 197:
+			dh.Pending = float64(a.RawValue)
+		case 187, 198:
+			if v := float64(a.RawValue); v > dh.Uncorrected {
+				dh.Uncorrected = v
+			}
+		case 231, 202:
+			dh.MediaType = "SSD"
+			dh.Wear = 100 - float64(a.RawValue)
+		}
+	}
+	return dh
+}
+
+// evaluateSmartReports сверяет свежие атрибуты с порогами и историей, обновляет
+// state (rolling window ограничен HistoryWindow точками) и возвращает строки
+// проблем в том же формате, что и остальные записи currentProblems.
+func evaluateSmartReports(reports []SmartDiskReport, cfg SmartConfig, state *smartState) map[string]string {
+	problems := make(map[string]string)
+	now := time.Now()
+
+	for _, report := range reports {
+		deviceKey := fmt.Sprintf("smart:%s(%s)", report.Device, report.Model)
+
+		if state.Devices[report.Device] == nil {
+			state.Devices[report.Device] = make(map[string][]smartAttributeReading)
+		}
+
+		for _, attr := range report.Attributes {
+			threshold, ok := cfg.Thresholds[fmt.Sprintf("%d", attr.ID)]
+			if !ok {
+				continue
+			}
+
+			attrKey := fmt.Sprintf("%d", attr.ID)
+			history := append(state.Devices[report.Device][attrKey], smartAttributeReading{Timestamp: now, RawValue: attr.RawValue})
+			sort.Slice(history, func(i, j int) bool { return history[i].Timestamp.Before(history[j].Timestamp) })
+			if len(history) > cfg.HistoryWindow {
+				history = history[len(history)-cfg.HistoryWindow:]
+			}
+			state.Devices[report.Device][attrKey] = history
+
+			delta := int64(0)
+			if len(history) > 0 {
+				delta = attr.RawValue - history[0].RawValue
+			}
+
+			// Warn/Critical in cfg.Thresholds are written in raw-value semantics
+			// (e.g. SSD_Life_Left's 10/5 mean "life remaining", lower is worse),
+			// so inverted attributes compare raw against them with <= instead of
+			// flipping the value and reusing the >= comparison.
+			level := ""
+			if isInvertedSmartAttr(attr.ID) {
+				if attr.RawValue <= threshold.Critical && threshold.Critical > 0 {
+					level = "CRITICAL"
+				} else if attr.RawValue <= threshold.Warn && threshold.Warn > 0 {
+					level = "WARN"
+				}
+			} else {
+				if attr.RawValue >= threshold.Critical && threshold.Critical > 0 {
+					level = "CRITICAL"
+				} else if attr.RawValue >= threshold.Warn && threshold.Warn > 0 {
+					level = "WARN"
+				}
+			}
+			if level == "" && threshold.MaxDeltaPerWindow > 0 && delta > threshold.MaxDeltaPerWindow {
+				level = "WARN (fast growth)"
+			}
+
+			if level != "" {
+				problems[deviceKey] = fmt.Sprintf("%s - SMART %s (#%d): raw=%d delta_over_window=%d level=%s",
+					deviceKey, threshold.Name, attr.ID, attr.RawValue, delta, level)
+			}
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPNotifier delivers alerts as plain-text emails over SMTP, upgrading the
+// connection with STARTTLS when the server advertises it; otherwise the
+// session stays unencrypted.
+type SMTPNotifier struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+func newSMTPNotifier(cfg NotifierConfig) *SMTPNotifier {
+	return &SMTPNotifier{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.SMTPFrom,
+		To:       cfg.SMTPTo,
+	}
+}
+
+// Name identifies this backend in diskmon_notification_send_total.
+func (s *SMTPNotifier) Name() string { return "smtp" }
+
+// Send ignores attachments (plain SMTP body only) and the silent hint, which
+// has no equivalent in email.
+func (s *SMTPNotifier) Send(ctx context.Context, subject, body string, attachments ...Attachment) error {
+	addr := net.JoinHostPort(s.Host, fmt.Sprintf("%d", s.Port))
+
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n",
+		s.From, strings.Join(s.To, ", "), subject, body)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("smtp: failed to connect to %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		return fmt.Errorf("smtp: failed to create client: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.Host}); err != nil {
+			return fmt.Errorf("smtp: STARTTLS failed: %w", err)
+		}
+	}
+
+	if s.Username != "" {
+		auth := smtp.PlainAuth("", s.Username, s.Password, s.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp: auth failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.From); err != nil {
+		return fmt.Errorf("smtp: MAIL FROM failed: %w", err)
+	}
+	for _, to := range s.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("smtp: RCPT TO %s failed: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp: DATA failed: %w", err)
+	}
+	if _, err := w.Write([]byte(msg)); err != nil {
+		return fmt.Errorf("smtp: failed to write message body: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp: failed to close message body: %w", err)
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	telegramOffsetFileName = "telegram_offset.json"
+	telegramPollTimeout    = 30 // секунд, используется в long polling getUpdates
+	defaultLogTailLines    = 200
+)
+
+var (
+	muteMu     sync.Mutex
+	mutedUntil time.Time
+)
+
+// telegramUpdate - минимальный срез структуры Update из Telegram Bot API,
+// нужный для long-polling команд.
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		MessageID int64 `json:"message_id"`
+		Chat      struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		Text string `json:"text"`
+	} `json:"message"`
+}
+
+type telegramGetUpdatesResponse struct {
+	OK     bool             `json:"ok"`
+	Result []telegramUpdate `json:"result"`
+}
+
+func telegramOffsetFilePath() string {
+	exePath, err := os.Executable()
+	if err != nil {
+		return telegramOffsetFileName
+	}
+	return filepath.Join(filepath.Dir(exePath), telegramOffsetFileName)
+}
+
+func loadTelegramOffset() int64 {
+	data, err := os.ReadFile(telegramOffsetFilePath())
+	if err != nil {
+		return 0
+	}
+	offset, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return offset
+}
+
+func saveTelegramOffset(offset int64) {
+	err := os.WriteFile(telegramOffsetFilePath(), []byte(strconv.FormatInt(offset, 10)), 0666)
+	if err != nil {
+		slog.Warn("Failed to persist telegram offset", "err", err)
+	}
+}
+
+// runTelegramBot опрашивает getUpdates в режиме long polling и обрабатывает
+// команды от AppConfig.TelegramChatID, пока ctx не будет отменен при остановке сервиса.
+func runTelegramBot(ctx context.Context) {
+	offset := loadTelegramOffset()
+	slog.Info("Telegram command listener starting.")
+
+	client := &http.Client{Timeout: time.Duration(telegramPollTimeout+10) * time.Second}
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Telegram command listener stopping.")
+			return
+		default:
+		}
+
+		updates, err := getTelegramUpdates(client, offset)
+		if err != nil {
+			slog.Warn("getUpdates failed", "err", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			saveTelegramOffset(offset)
+
+			if u.Message == nil {
+				continue
+			}
+
+			chatID := strconv.FormatInt(u.Message.Chat.ID, 10)
+			if chatID != AppConfig.TelegramChatID {
+				slog.Warn("Ignoring message from unexpected chat_id", "chat_id", chatID)
+				continue
+			}
+
+			handleTelegramCommand(strings.TrimSpace(u.Message.Text))
+		}
+	}
+}
+
+func getTelegramUpdates(client *http.Client, offset int64) ([]telegramUpdate, error) {
+	apiURL := fmt.Sprintf("%s/getUpdates?offset=%d&timeout=%d", telegramApiBase, offset, telegramPollTimeout)
+	resp, err := client.Get(apiURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var parsed telegramGetUpdatesResponse
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse getUpdates response: %w", err)
+	}
+	if !parsed.OK {
+		return nil, fmt.Errorf("getUpdates returned not-ok response: %s", string(body))
+	}
+
+	return parsed.Result, nil
+}
+
+// sendTelegramReply sends a Telegram-only reply to an inbound bot command. It
+// talks straight to the Telegram API instead of going through activeNotifier,
+// so enabling an extra notifier backend (SMTP, webhook, Matrix) never turns a
+// command reply into an alert blasted out on every other channel too.
+func sendTelegramReply(message string, silent bool) {
+	hostname, _ := os.Hostname()
+	fullMessage := fmt.Sprintf("🖥️ **Host:** `%s`\n\n%s", hostname, message)
+
+	var err error
+	if len(fullMessage) > telegramMsgLimit {
+		err = sendTelegramDocument(fullMessage, silent)
+	} else {
+		err = sendTelegramText(fullMessage, silent)
+	}
+	if err != nil {
+		slog.Error("Failed to send telegram command reply", "err", err)
+	}
+}
+
+// handleTelegramCommand dispatches a single inbound command line to its handler.
+func handleTelegramCommand(text string) {
+	if text == "" || !strings.HasPrefix(text, "/") {
+		return
+	}
+
+	fields := strings.Fields(text)
+	command := fields[0]
+	argsStr := strings.TrimSpace(strings.TrimPrefix(text, command))
+
+	slog.Info("Received telegram command", "command", text)
+
+	switch command {
+	case "/status":
+		handleStatusCommand()
+	case "/check":
+		handleCheckCommand()
+	case "/mute":
+		handleMuteCommand(argsStr)
+	case "/logs":
+		handleLogsCommand(argsStr)
+	case "/disks":
+		handleDisksCommand()
+	default:
+		sendTelegramReply(fmt.Sprintf("Unknown command: %s", command), false)
+	}
+}
+
+func handleStatusCommand() {
+	snapshot := snapshotLastErrorState()
+	if len(snapshot) == 0 {
+		sendTelegramReply("✅ Status: no active problems.", false)
+		return
+	}
+
+	var problems []string
+	for _, problem := range snapshot {
+		problems = append(problems, problem)
+	}
+	sendTelegramReply(fmt.Sprintf("ℹ️ Active problems:\n\n`%s`", strings.Join(problems, "`\n`")), false)
+}
+
+func handleCheckCommand() {
+	sendTelegramReply("🔄 Running an on-demand disk check...", false)
+	checkDiskStatusAndNotify()
+}
+
+// handleMuteCommand принимает длительность в формате time.ParseDuration (например "30m", "2h").
+func handleMuteCommand(durationStr string) {
+	if durationStr == "" {
+		sendTelegramReply("Usage: /mute <duration>, e.g. /mute 2h", false)
+		return
+	}
+
+	d, err := time.ParseDuration(durationStr)
+	if err != nil {
+		sendTelegramReply(fmt.Sprintf("Invalid duration %q: %v", durationStr, err), false)
+		return
+	}
+
+	muteMu.Lock()
+	mutedUntil = time.Now().Add(d)
+	until := mutedUntil
+	muteMu.Unlock()
+
+	sendTelegramReply(fmt.Sprintf("🔇 Notifications muted until %s.", until.Format(time.RFC3339)), false)
+}
+
+// isMuted reports whether notifications are currently suppressed by /mute.
+func isMuted() bool {
+	muteMu.Lock()
+	defer muteMu.Unlock()
+	return time.Now().Before(mutedUntil)
+}
+
+func handleLogsCommand(nStr string) {
+	n := defaultLogTailLines
+	if nStr != "" {
+		if parsed, err := strconv.Atoi(nStr); err == nil && parsed > 0 {
+			n = parsed
+		}
+	}
+
+	content, err := tailLogFile(n)
+	if err != nil {
+		sendTelegramReply(fmt.Sprintf("Failed to read log file: %v", err), false)
+		return
+	}
+
+	if err := sendTelegramDocument(content, false); err != nil {
+		slog.Warn("Failed to send log tail document", "err", err)
+		sendTelegramReply(fmt.Sprintf("Failed to send log tail: %v", err), false)
+	}
+}
+
+// tailLogFile returns the last n lines of DiskMonitorService.log.
+func tailLogFile(n int) (string, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	logFilePath := filepath.Join(filepath.Dir(exePath), logFileName)
+
+	data, err := os.ReadFile(logFilePath)
+	if err != nil {
+		return "", err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func handleDisksCommand() {
+	disks, err := healthCollector.Collect(context.Background())
+	if err != nil {
+		sendTelegramReply(fmt.Sprintf("Failed to collect disk snapshot: %v", err), false)
+		return
+	}
+
+	if len(disks) == 0 {
+		sendTelegramReply("No physical disks reported.", false)
+		return
+	}
+
+	lines := make([]string, 0, len(disks))
+	for _, d := range disks {
+		lines = append(lines, d.line())
+	}
+
+	sendTelegramReply(fmt.Sprintf("💽 Full disk snapshot:\n\n`%s`", strings.Join(lines, "\n")), false)
+}
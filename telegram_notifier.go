@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// TelegramNotifier implements Notifier on top of the Telegram Bot API. It is
+// always part of activeNotifier since TelegramToken/TelegramChatID are mandatory.
+type TelegramNotifier struct{}
+
+// Name identifies this backend in diskmon_notification_send_total.
+func (t *TelegramNotifier) Name() string { return "telegram" }
+
+// Send delivers subject+body as a single message, falling back to a text
+// document when the formatted message exceeds telegramMsgLimit, and sends any
+// attachments as separate documents. Honors the silent hint from ctx.
+func (t *TelegramNotifier) Send(ctx context.Context, subject, body string, attachments ...Attachment) error {
+	hostname, _ := os.Hostname()
+	fullMessage := fmt.Sprintf("🖥️ **Host:** `%s`\n\n**%s**\n\n%s", hostname, subject, body)
+	silent := silentFromContext(ctx)
+
+	for _, a := range attachments {
+		if err := sendTelegramFile(a.Filename, a.Content, silent); err != nil {
+			return fmt.Errorf("failed to send attachment %s: %w", a.Filename, err)
+		}
+	}
+
+	if strings.TrimSpace(body) == "" && len(attachments) > 0 {
+		return nil
+	}
+
+	if len(fullMessage) > telegramMsgLimit {
+		return sendTelegramDocument(fullMessage, silent)
+	}
+	return sendTelegramText(fullMessage, silent)
+}
+
+// sendTelegramText sends a short message.
+func sendTelegramText(message string, silent bool) error {
+	apiURL := fmt.Sprintf("%s/sendMessage", telegramApiBase)
+	params := url.Values{}
+	params.Add("chat_id", AppConfig.TelegramChatID)
+	params.Add("text", message)
+	params.Add("parse_mode", "Markdown")
+	if silent {
+		params.Add("disable_notification", "true")
+	}
+
+	resp, err := http.PostForm(apiURL, params)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request failed with status %s: %s", resp.Status, string(body))
+	}
+	return nil
+}
+
+// sendTelegramDocument sends a long message as a text file with an auto-generated name.
+func sendTelegramDocument(content string, silent bool) error {
+	now := time.Now().Format("2006-01-02_15-04-05")
+	hostname, _ := os.Hostname()
+	fileName := fmt.Sprintf("log_%s_%s.txt", hostname, now)
+	return sendTelegramFile(fileName, []byte(content), silent)
+}
+
+// sendTelegramFile sends arbitrary named content as a Telegram document.
+func sendTelegramFile(fileName string, content []byte, silent bool) error {
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+
+	w.WriteField("chat_id", AppConfig.TelegramChatID)
+	if silent {
+		w.WriteField("disable_notification", "true")
+	}
+
+	fw, err := w.CreateFormFile("document", fileName)
+	if err != nil {
+		return fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := fw.Write(content); err != nil {
+		return fmt.Errorf("failed to write content to form file: %w", err)
+	}
+	w.Close()
+
+	apiURL := fmt.Sprintf("%s/sendDocument", telegramApiBase)
+	req, err := http.NewRequest("POST", apiURL, &b)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request failed with status %s: %s", resp.Status, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultWebhookTemplate is used when NotifierConfig.WebhookTemplate is empty;
+// it renders a small JSON envelope with the raw subject/body.
+const defaultWebhookTemplate = `{"subject":{{.Subject | json}},"body":{{.Body | json}}}`
+
+// webhookPayload is the data made available to WebhookTemplate.
+type webhookPayload struct {
+	Subject string
+	Body    string
+}
+
+// WebhookNotifier POSTs a JSON payload (built from a configurable text/template)
+// to an arbitrary URL with configurable headers.
+type WebhookNotifier struct {
+	URL      string
+	Headers  map[string]string
+	Template string
+}
+
+func newWebhookNotifier(cfg NotifierConfig) *WebhookNotifier {
+	tmpl := cfg.WebhookTemplate
+	if tmpl == "" {
+		tmpl = defaultWebhookTemplate
+	}
+	return &WebhookNotifier{URL: cfg.WebhookURL, Headers: cfg.WebhookHeaders, Template: tmpl}
+}
+
+// Name identifies this backend in diskmon_notification_send_total.
+func (w *WebhookNotifier) Name() string { return "webhook" }
+
+// Send ignores attachments and the silent hint; neither has a generic webhook equivalent.
+func (w *WebhookNotifier) Send(ctx context.Context, subject, body string, attachments ...Attachment) error {
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{
+		"json": webhookJSONString,
+	}).Parse(w.Template)
+	if err != nil {
+		return fmt.Errorf("webhook: failed to parse template: %w", err)
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, webhookPayload{Subject: subject, Body: body}); err != nil {
+		return fmt.Errorf("webhook: failed to render template: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(rendered.Bytes()))
+	if err != nil {
+		return fmt.Errorf("webhook: failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.Headers {
+		req.Header.Set(k, v)
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("webhook: request failed with status %s: %s", resp.Status, string(respBody))
+	}
+	return nil
+}
+
+// webhookJSONString marshals a string for safe embedding into the JSON template output.
+func webhookJSONString(s string) (string, error) {
+	encoded, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(encoded)), nil
+}